@@ -1,11 +1,13 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
-	"fmt"
 	"io/ioutil"
 	"net/http"
-	"sort"
+	"net/url"
+	"strconv"
+	"strings"
 )
 
 // Account represents an account on Section
@@ -18,32 +20,190 @@ type Account struct {
 	Requires2FA bool   `json:"requires_2fa"`
 }
 
-// Accounts returns a list of account the current user has access to.
-func Accounts() (as []Account, err error) {
+// AccountSortField is a field accounts can be sorted by server-side.
+type AccountSortField string
+
+// Supported values for AccountListOptions.SortBy.
+const (
+	AccountSortByID      AccountSortField = "id"
+	AccountSortByName    AccountSortField = "name"
+	AccountSortByCreated AccountSortField = "created"
+)
+
+// AccountListOptions controls pagination, filtering and sorting for AccountsIter.
+type AccountListOptions struct {
+	// PageSize is the number of accounts to request per page. The API's
+	// default is used if unset.
+	PageSize int
+	// NameContains filters accounts whose name contains this substring.
+	NameContains string
+	// AdminOnly restricts results to accounts the user administers.
+	AdminOnly bool
+	// SortBy orders results server-side. Defaults to AccountSortByID.
+	SortBy AccountSortField
+}
+
+func (o AccountListOptions) query() url.Values {
+	q := url.Values{}
+	if o.PageSize > 0 {
+		q.Set("page_size", strconv.Itoa(o.PageSize))
+	}
+	if o.NameContains != "" {
+		q.Set("name_contains", o.NameContains)
+	}
+	if o.AdminOnly {
+		q.Set("admin_only", "true")
+	}
+	if o.SortBy != "" {
+		q.Set("sort_by", string(o.SortBy))
+	}
+	return q
+}
+
+// accountPage is the paginated response shape returned by the account list
+// endpoint; Next is a JSON cursor used when the API doesn't return a Link
+// header.
+type accountPage struct {
+	Accounts []Account `json:"accounts"`
+	Next     string    `json:"next"`
+}
+
+// AccountIterator pages through accounts the current user has access to,
+// fetching the next page lazily as Next is called.
+type AccountIterator struct {
+	nextURL *url.URL
+	buf     []Account
+	err     error
+	done    bool
+}
+
+// AccountsIter returns an iterator over the accounts the current user has
+// access to, matching opts.
+func AccountsIter(opts AccountListOptions) *AccountIterator {
 	u := BaseURL()
 	u.Path += "/account"
+	u.RawQuery = opts.query().Encode()
 
-	resp, err := request(http.MethodGet, u, nil)
+	return &AccountIterator{nextURL: &u}
+}
+
+// Next advances the iterator, fetching another page if needed, and reports
+// whether an account is available. Once Next returns false, call Err to
+// check whether iteration stopped due to an error.
+func (it *AccountIterator) Next() (Account, bool) {
+	for len(it.buf) == 0 && !it.done {
+		it.fetch()
+	}
+
+	if len(it.buf) == 0 {
+		return Account{}, false
+	}
+
+	a := it.buf[0]
+	it.buf = it.buf[1:]
+	return a, true
+}
+
+// Err returns the first error encountered while paging, if any.
+func (it *AccountIterator) Err() error {
+	return it.err
+}
+
+func (it *AccountIterator) fetch() {
+	ctx, cancel := context.WithTimeout(context.Background(), Timeout)
+	defer cancel()
+
+	resp, err := request(ctx, http.MethodGet, *it.nextURL, nil)
 	if err != nil {
-		return as, err
+		it.err = err
+		it.done = true
+		return
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return as, fmt.Errorf("request failed with status %s and transaction ID %s", resp.Status, resp.Header["Aperture-Tx-Id"][0])
+		switch resp.StatusCode {
+		case http.StatusUnauthorized:
+			it.err = ErrStatusUnauthorized
+		case http.StatusForbidden:
+			it.err = ErrStatusForbidden
+		default:
+			it.err = prettyTxIDError(resp)
+		}
+		it.done = true
+		return
 	}
 
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		return as, err
+		it.err = err
+		it.done = true
+		return
+	}
+
+	var page accountPage
+	if err := json.Unmarshal(body, &page); err == nil && page.Accounts != nil {
+		it.buf = page.Accounts
+	} else {
+		var flat []Account
+		if err := json.Unmarshal(body, &flat); err != nil {
+			it.err = err
+			it.done = true
+			return
+		}
+		it.buf = flat
+	}
+
+	next := page.Next
+	if next == "" {
+		next = nextLinkFromHeader(resp.Header.Get("Link"))
 	}
 
-	err = json.Unmarshal(body, &as)
+	if next == "" {
+		it.done = true
+		return
+	}
+
+	nextURL, err := url.Parse(next)
 	if err != nil {
-		return as, err
+		it.err = err
+		it.done = true
+		return
+	}
+	it.nextURL = nextURL
+}
+
+// nextLinkFromHeader extracts the rel="next" target from an RFC 5988 Link
+// header, returning "" if there isn't one.
+func nextLinkFromHeader(link string) string {
+	for _, part := range strings.Split(link, ",") {
+		segments := strings.Split(part, ";")
+		if len(segments) < 2 {
+			continue
+		}
+
+		target := strings.Trim(strings.TrimSpace(segments[0]), "<>")
+		for _, seg := range segments[1:] {
+			seg = strings.TrimSpace(seg)
+			if seg == `rel="next"` {
+				return target
+			}
+		}
+	}
+	return ""
+}
+
+// Accounts returns every account the current user has access to, sorted by
+// ID. It's a thin wrapper around AccountsIter for callers that just want the
+// full list.
+func Accounts() (as []Account, err error) {
+	it := AccountsIter(AccountListOptions{SortBy: AccountSortByID})
+	for {
+		a, ok := it.Next()
+		if !ok {
+			break
+		}
+		as = append(as, a)
 	}
-	sort.Slice(as, func(i, j int) bool {
-		return as[i].ID < as[j].ID
-	})
-	return as, err
+	return as, it.Err()
 }
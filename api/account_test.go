@@ -0,0 +1,77 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccountsIterFollowsLinkHeader(t *testing.T) {
+	assert := assert.New(t)
+
+	// Setup
+	pages := [][]Account{
+		{{ID: 1, AccountName: "first"}},
+		{{ID: 2, AccountName: "second"}},
+	}
+	requests := 0
+	var ts *httptest.Server
+	ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page := pages[requests]
+		requests++
+		if requests < len(pages) {
+			w.Header().Set("Link", fmt.Sprintf(`<%s/account?page=%d>; rel="next"`, ts.URL, requests+1))
+		}
+		w.WriteHeader(http.StatusOK)
+		assert.NoError(json.NewEncoder(w).Encode(page))
+	}))
+
+	u, err := url.Parse(ts.URL)
+	assert.NoError(err)
+
+	it := AccountsIter(AccountListOptions{})
+	it.nextURL = u
+
+	// Invoke
+	var got []Account
+	for {
+		a, ok := it.Next()
+		if !ok {
+			break
+		}
+		got = append(got, a)
+	}
+
+	// Test
+	assert.NoError(it.Err())
+	assert.Equal(2, requests)
+	assert.Equal([]Account{{ID: 1, AccountName: "first"}, {ID: 2, AccountName: "second"}}, got)
+}
+
+func TestAccountsRoutesErrorsThroughPrettyTxIDError(t *testing.T) {
+	assert := assert.New(t)
+
+	// Setup
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+
+	u, err := url.Parse(ts.URL)
+	assert.NoError(err)
+
+	it := AccountsIter(AccountListOptions{})
+	it.nextURL = u
+
+	// Invoke
+	_, ok := it.Next()
+
+	// Test
+	assert.False(ok)
+	assert.Error(it.Err())
+	assert.Regexp("request failed with status", it.Err())
+}
@@ -0,0 +1,154 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+)
+
+// Version is the sectionctl version sent in the User-Agent header. It is
+// overridden at build time via -ldflags.
+var Version = "dev"
+
+// Timeout is the max duration to wait for an API response.
+var Timeout = 30 * time.Second
+
+// Token is the Section API token sent with every request as section-token.
+var Token string
+
+// ErrStatusUnauthorized is returned when the API rejects the request's credentials.
+var ErrStatusUnauthorized = fmt.Errorf("invalid credentials, please check your Section API token")
+
+// ErrStatusForbidden is returned when the API refuses to perform the requested action.
+var ErrStatusForbidden = fmt.Errorf("you do not have permission to perform this action")
+
+const keyringService = "sectionctl"
+
+// BaseURL returns the base URL for the Section API, which can be overridden
+// with the SECTION_API_URL environment variable.
+func BaseURL() url.URL {
+	base := os.Getenv("SECTION_API_URL")
+	if base == "" {
+		base = "https://aperture.section.io/api/v1"
+	}
+
+	u, err := url.Parse(base)
+	if err != nil {
+		u, _ = url.Parse("https://aperture.section.io/api/v1")
+	}
+	return *u
+}
+
+func setCommonHeaders(req *http.Request, headers ...http.Header) {
+	req.Header.Set("User-Agent", fmt.Sprintf("sectionctl (%s)", Version))
+	if token := current2FASessionToken(); token != "" {
+		req.Header.Set(sessionTokenHeader, token)
+	}
+
+	for _, hs := range headers {
+		for k, vs := range hs {
+			for _, v := range vs {
+				req.Header.Add(k, v)
+			}
+		}
+	}
+}
+
+// request performs an HTTP request against the Section API, attaching the
+// configured credentials and any extra headers. Rate-limited (429) and
+// transient (502/503/504) responses are retried per the current retry
+// policy (see SetRetryPolicy). A 401/403 carrying an X-Section-2FA-Required
+// header is surfaced as an *Err2FARequired instead of being returned as a
+// plain response, so callers only have to handle the happy path plus the
+// 2FA challenge.
+func request(ctx context.Context, method string, u url.URL, body io.Reader, headers ...http.Header) (*http.Response, error) {
+	return doRequest(ctx, method, u, body, true, headers...)
+}
+
+func doRequest(ctx context.Context, method string, u url.URL, body io.Reader, allowAuthRefresh bool, headers ...http.Header) (*http.Response, error) {
+	var bodyBytes []byte
+	if body != nil {
+		b, err := ioutil.ReadAll(body)
+		if err != nil {
+			return nil, err
+		}
+		bodyBytes = b
+		body = bytes.NewReader(bodyBytes)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	setCommonHeaders(req, headers...)
+	if err := currentAuthenticator().Authenticate(req); err != nil {
+		return nil, err
+	}
+	if len(SigningKey) > 0 {
+		signRequest(req, bodyBytes, SigningKey)
+	}
+
+	rreq, err := retryablehttp.FromRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := retryableClient().Do(rreq)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		if challengeID := resp.Header.Get("X-Section-2FA-Required"); challengeID != "" {
+			resp.Body.Close()
+			return nil, &Err2FARequired{ChallengeID: challengeID}
+		}
+	}
+
+	if resp.StatusCode == http.StatusUnauthorized && allowAuthRefresh {
+		if ra, ok := currentAuthenticator().(refreshableAuthenticator); ok {
+			resp.Body.Close()
+			if err := ra.Refresh(); err != nil {
+				return nil, err
+			}
+
+			var retryBody io.Reader
+			if bodyBytes != nil {
+				retryBody = bytes.NewReader(bodyBytes)
+			}
+			return doRequest(ctx, method, u, retryBody, false, headers...)
+		}
+	}
+
+	return resp, nil
+}
+
+// prettyTxIDError turns a non-200 response into a human readable error that
+// includes the Aperture transaction ID when one is present, so support can
+// trace the request server-side.
+func prettyTxIDError(resp *http.Response) error {
+	var txID string
+	if ids := resp.Header["Aperture-Tx-Id"]; len(ids) > 0 {
+		txID = ids[0]
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		if txID != "" {
+			return fmt.Errorf("too many requests. Please wait a few minutes and try again (Section Transaction ID: %s)", txID)
+		}
+		return fmt.Errorf("too many requests. Please wait a few minutes and try again")
+	}
+
+	if txID != "" {
+		return fmt.Errorf("request failed with status %s (Section Transaction ID: %s)", resp.Status, txID)
+	}
+	return fmt.Errorf("request failed with status %s", resp.Status)
+}
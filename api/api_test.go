@@ -2,11 +2,13 @@ package api
 
 import (
 	"context"
+	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -129,6 +131,205 @@ func TestAPIClientUsesCredentialsIfSpecified(t *testing.T) {
 	Token = ""
 }
 
+func TestAPIClientUsesAuthenticatorModes(t *testing.T) {
+	cases := []struct {
+		name          string
+		authenticator func() Authenticator
+		assertHeaders func(t *testing.T, assert *assert.Assertions, r *http.Request)
+	}{
+		{
+			name:          "section token",
+			authenticator: func() Authenticator { return sectionTokenAuthenticator{} },
+			assertHeaders: func(t *testing.T, assert *assert.Assertions, r *http.Request) {
+				assert.Equal("s3cr3t", r.Header.Get("section-token"))
+			},
+		},
+		{
+			name: "basic auth",
+			authenticator: func() Authenticator {
+				return BasicAuthenticator{Username: "svc-account", Password: "hunter2"}
+			},
+			assertHeaders: func(t *testing.T, assert *assert.Assertions, r *http.Request) {
+				user, pass, ok := r.BasicAuth()
+				assert.True(ok)
+				assert.Equal("svc-account", user)
+				assert.Equal("hunter2", pass)
+			},
+		},
+		{
+			name: "oidc client credentials",
+			authenticator: func() Authenticator {
+				issuer := helperOIDCIssuer(t, "access-token-123", 300)
+				return NewOIDCAuthenticator(issuer.URL, "client-id", "client-secret")
+			},
+			assertHeaders: func(t *testing.T, assert *assert.Assertions, r *http.Request) {
+				assert.Equal("Bearer access-token-123", r.Header.Get("Authorization"))
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			// Setup
+			keyring.MockInit()
+			Token = "s3cr3t"
+			var req *http.Request
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				req = r
+				w.WriteHeader(http.StatusOK)
+			}))
+			SetAuthenticator(c.authenticator())
+
+			u, err := url.Parse(ts.URL)
+			assert.NoError(err)
+
+			ctx, cancel := context.WithTimeout(context.Background(), Timeout)
+			defer cancel()
+
+			// Invoke
+			resp, err := request(ctx, http.MethodGet, *u, nil)
+
+			// Test
+			assert.NoError(err)
+			assert.Equal(http.StatusOK, resp.StatusCode)
+			c.assertHeaders(t, assert, req)
+
+			// Teardown
+			Token = ""
+			SetAuthenticator(nil)
+		})
+	}
+}
+
+// refreshingAuthenticator is a minimal refreshableAuthenticator test double:
+// it sends a stale bearer token until Refresh is called, at which point it
+// switches to a fresh one.
+type refreshingAuthenticator struct {
+	token string
+}
+
+func (a *refreshingAuthenticator) Authenticate(req *http.Request) error {
+	req.Header.Set("Authorization", "Bearer "+a.token)
+	return nil
+}
+
+func (a *refreshingAuthenticator) Refresh() error {
+	a.token = "refreshed-token"
+	return nil
+}
+
+func TestAPIClientRefreshesAuthenticatorOn401(t *testing.T) {
+	assert := assert.New(t)
+
+	// Setup
+	attempt := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempt++
+		if attempt == 1 {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		assert.Equal("Bearer refreshed-token", r.Header.Get("Authorization"))
+		w.WriteHeader(http.StatusOK)
+	}))
+	SetAuthenticator(&refreshingAuthenticator{token: "stale-token"})
+
+	u, err := url.Parse(ts.URL)
+	assert.NoError(err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), Timeout)
+	defer cancel()
+
+	// Invoke
+	resp, err := request(ctx, http.MethodGet, *u, nil)
+
+	// Test
+	assert.NoError(err)
+	assert.Equal(http.StatusOK, resp.StatusCode)
+	assert.Equal(2, attempt)
+
+	// Teardown
+	SetAuthenticator(nil)
+}
+
+func TestAPIClientResendsBodyWhenRetryingAfterAuthRefresh(t *testing.T) {
+	assert := assert.New(t)
+
+	// Setup
+	attempt := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempt++
+		if attempt == 1 {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		body, err := ioutil.ReadAll(r.Body)
+		assert.NoError(err)
+		assert.Equal(`{"name":"new-account"}`, string(body))
+		w.WriteHeader(http.StatusOK)
+	}))
+	SetAuthenticator(&refreshingAuthenticator{token: "stale-token"})
+
+	u, err := url.Parse(ts.URL)
+	assert.NoError(err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), Timeout)
+	defer cancel()
+
+	// Invoke
+	resp, err := request(ctx, http.MethodPost, *u, strings.NewReader(`{"name":"new-account"}`))
+
+	// Test
+	assert.NoError(err)
+	assert.Equal(http.StatusOK, resp.StatusCode)
+	assert.Equal(2, attempt)
+
+	// Teardown
+	SetAuthenticator(nil)
+}
+
+// failingRefreshAuthenticator is a refreshableAuthenticator test double
+// whose Refresh always fails, so doRequest must surface that error instead
+// of returning a response with an already-closed body.
+type failingRefreshAuthenticator struct{}
+
+func (failingRefreshAuthenticator) Authenticate(req *http.Request) error {
+	req.Header.Set("Authorization", "Bearer stale-token")
+	return nil
+}
+
+func (failingRefreshAuthenticator) Refresh() error {
+	return fmt.Errorf("refresh failed")
+}
+
+func TestAPIClientReturnsErrorWhenAuthRefreshFails(t *testing.T) {
+	assert := assert.New(t)
+
+	// Setup
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	SetAuthenticator(failingRefreshAuthenticator{})
+
+	u, err := url.Parse(ts.URL)
+	assert.NoError(err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), Timeout)
+	defer cancel()
+
+	// Invoke
+	resp, err := request(ctx, http.MethodGet, *u, nil)
+
+	// Test
+	assert.Error(err)
+	assert.Nil(resp)
+
+	// Teardown
+	SetAuthenticator(nil)
+}
+
 func TestAPIrequestSendsHeaderArguments(t *testing.T) {
 	assert := assert.New(t)
 
@@ -0,0 +1,247 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zalando/go-keyring"
+)
+
+// Authenticator attaches credentials to an outgoing request. Exactly one is
+// active at a time; use SetAuthenticator to change it.
+type Authenticator interface {
+	Authenticate(req *http.Request) error
+}
+
+// refreshableAuthenticator is implemented by authenticators that can obtain
+// a fresh credential after a 401, so request() can retry once instead of
+// immediately surfacing ErrStatusUnauthorized.
+type refreshableAuthenticator interface {
+	Refresh() error
+}
+
+var (
+	authMu                sync.Mutex
+	explicitAuthenticator Authenticator
+	envAuthenticator      Authenticator
+)
+
+// SetAuthenticator overrides how request() authenticates outgoing requests.
+// Pass nil to fall back to the environment-derived default.
+func SetAuthenticator(a Authenticator) {
+	authMu.Lock()
+	defer authMu.Unlock()
+	explicitAuthenticator = a
+}
+
+func currentAuthenticator() Authenticator {
+	authMu.Lock()
+	defer authMu.Unlock()
+
+	if explicitAuthenticator != nil {
+		return explicitAuthenticator
+	}
+	if envAuthenticator == nil {
+		envAuthenticator = authenticatorFromEnv()
+	}
+	return envAuthenticator
+}
+
+// authenticatorFromEnv picks a default Authenticator based on which
+// credentials are present in the environment: an OIDC issuer takes
+// precedence over basic auth credentials, which in turn take precedence
+// over the static section-token.
+func authenticatorFromEnv() Authenticator {
+	if issuer := os.Getenv("SECTION_OIDC_ISSUER"); issuer != "" {
+		return NewOIDCAuthenticator(issuer, os.Getenv("SECTION_OIDC_CLIENT_ID"), os.Getenv("SECTION_OIDC_CLIENT_SECRET"))
+	}
+	if user := os.Getenv("SECTION_BASIC_USER"); user != "" {
+		return BasicAuthenticator{Username: user, Password: os.Getenv("SECTION_BASIC_PASSWORD")}
+	}
+	return sectionTokenAuthenticator{}
+}
+
+// sectionTokenAuthenticator sends the static Section token (the Token
+// package variable) as the section-token header. It's the default when no
+// other credentials are configured.
+type sectionTokenAuthenticator struct{}
+
+func (sectionTokenAuthenticator) Authenticate(req *http.Request) error {
+	if Token != "" {
+		req.Header.Set("section-token", Token)
+	}
+	return nil
+}
+
+// BasicAuthenticator authenticates with HTTP Basic auth, for machine users.
+type BasicAuthenticator struct {
+	Username string
+	Password string
+}
+
+// Authenticate implements Authenticator.
+func (a BasicAuthenticator) Authenticate(req *http.Request) error {
+	req.SetBasicAuth(a.Username, a.Password)
+	return nil
+}
+
+// OIDCAuthenticator fetches and caches bearer tokens from an OIDC/OAuth2
+// issuer using the client-credentials grant, keyed by issuer+client_id in
+// the keyring.
+type OIDCAuthenticator struct {
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time
+}
+
+// oidcCachedToken is what's persisted in the keyring for an OIDCAuthenticator,
+// so the real token lifetime (not some arbitrary local duration) survives
+// across processes.
+type oidcCachedToken struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// NewOIDCAuthenticator returns an Authenticator that fetches bearer tokens
+// from issuerURL using the client-credentials grant.
+func NewOIDCAuthenticator(issuerURL, clientID, clientSecret string) *OIDCAuthenticator {
+	return &OIDCAuthenticator{IssuerURL: issuerURL, ClientID: clientID, ClientSecret: clientSecret}
+}
+
+// Authenticate implements Authenticator.
+func (a *OIDCAuthenticator) Authenticate(req *http.Request) error {
+	token, err := a.getToken()
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	return nil
+}
+
+// Refresh implements refreshableAuthenticator. It discards the cached token,
+// in memory and in the keyring, and fetches a fresh one from the issuer so a
+// 401 doesn't just hand back the same stale credential.
+func (a *OIDCAuthenticator) Refresh() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.token = ""
+	a.expiresAt = time.Time{}
+	if err := keyring.Delete(keyringService, a.keyringKey()); err != nil && err != keyring.ErrNotFound {
+		return err
+	}
+
+	_, err := a.fetchAndCacheLocked()
+	return err
+}
+
+func (a *OIDCAuthenticator) keyringKey() string {
+	return fmt.Sprintf("%s:%s", a.IssuerURL, a.ClientID)
+}
+
+func (a *OIDCAuthenticator) getToken() (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.token != "" && time.Now().Before(a.expiresAt) {
+		return a.token, nil
+	}
+
+	if cached, ok := a.loadCachedToken(); ok {
+		a.token = cached.Token
+		a.expiresAt = cached.ExpiresAt
+		return a.token, nil
+	}
+
+	return a.fetchAndCacheLocked()
+}
+
+// loadCachedToken reads a still-valid token from the keyring, keyed by its
+// real expiry rather than an arbitrary local duration.
+func (a *OIDCAuthenticator) loadCachedToken() (oidcCachedToken, bool) {
+	raw, err := keyring.Get(keyringService, a.keyringKey())
+	if err != nil || raw == "" {
+		return oidcCachedToken{}, false
+	}
+
+	var cached oidcCachedToken
+	if err := json.Unmarshal([]byte(raw), &cached); err != nil {
+		return oidcCachedToken{}, false
+	}
+	if !time.Now().Before(cached.ExpiresAt) {
+		return oidcCachedToken{}, false
+	}
+
+	return cached, true
+}
+
+// fetchAndCacheLocked fetches a new token from the issuer and caches it both
+// in memory and in the keyring. Callers must hold a.mu.
+func (a *OIDCAuthenticator) fetchAndCacheLocked() (string, error) {
+	token, expiresIn, err := a.fetchToken()
+	if err != nil {
+		return "", err
+	}
+
+	a.token = token
+	a.expiresAt = time.Now().Add(expiresIn)
+
+	cached, err := json.Marshal(oidcCachedToken{Token: token, ExpiresAt: a.expiresAt})
+	if err != nil {
+		return "", err
+	}
+	if err := keyring.Set(keyringService, a.keyringKey(), string(cached)); err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+func (a *OIDCAuthenticator) fetchToken() (token string, expiresIn time.Duration, err error) {
+	form := url.Values{}
+	form.Set("grant_type", "client_credentials")
+
+	req, err := http.NewRequest(http.MethodPost, strings.TrimRight(a.IssuerURL, "/")+"/oauth2/token", strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", 0, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(a.ClientID, a.ClientSecret)
+
+	client := &http.Client{Timeout: Timeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", 0, prettyTxIDError(resp)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", 0, err
+	}
+
+	var result struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return "", 0, err
+	}
+
+	return result.AccessToken, time.Duration(result.ExpiresIn) * time.Second, nil
+}
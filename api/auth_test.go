@@ -0,0 +1,135 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zalando/go-keyring"
+)
+
+// helperOIDCIssuer returns a test double for an OIDC issuer's token
+// endpoint that always hands back accessToken with the given expiresIn
+// (seconds), for use with NewOIDCAuthenticator.
+func helperOIDCIssuer(t *testing.T, accessToken string, expiresIn int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/oauth2/token", r.URL.Path)
+		user, pass, ok := r.BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, "client-id", user)
+		assert.Equal(t, "client-secret", pass)
+
+		w.Header().Set("Content-Type", "application/json")
+		assert.NoError(t, json.NewEncoder(w).Encode(struct {
+			AccessToken string `json:"access_token"`
+			ExpiresIn   int    `json:"expires_in"`
+		}{AccessToken: accessToken, ExpiresIn: expiresIn}))
+	}))
+}
+
+func TestOIDCAuthenticatorRefreshFetchesFreshTokenFromIssuer(t *testing.T) {
+	assert := assert.New(t)
+
+	// Setup
+	keyring.MockInit()
+	requests := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+		token := fmt.Sprintf("token-%d", requests)
+		assert.NoError(json.NewEncoder(w).Encode(struct {
+			AccessToken string `json:"access_token"`
+			ExpiresIn   int    `json:"expires_in"`
+		}{AccessToken: token, ExpiresIn: 300}))
+	}))
+	defer ts.Close()
+
+	a := NewOIDCAuthenticator(ts.URL, "client-id", "client-secret")
+
+	req, err := http.NewRequest(http.MethodGet, "http://example.test", nil)
+	assert.NoError(err)
+	assert.NoError(a.Authenticate(req))
+	assert.Equal("Bearer token-1", req.Header.Get("Authorization"))
+
+	// Invoke
+	assert.NoError(a.Refresh())
+
+	// Test
+	req2, err := http.NewRequest(http.MethodGet, "http://example.test", nil)
+	assert.NoError(err)
+	assert.NoError(a.Authenticate(req2))
+	assert.Equal("Bearer token-2", req2.Header.Get("Authorization"))
+	assert.Equal(2, requests)
+}
+
+func TestOIDCAuthenticatorReusesCachedTokenAcrossInstances(t *testing.T) {
+	assert := assert.New(t)
+
+	// Setup
+	keyring.MockInit()
+	requests := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+		assert.NoError(json.NewEncoder(w).Encode(struct {
+			AccessToken string `json:"access_token"`
+			ExpiresIn   int    `json:"expires_in"`
+		}{AccessToken: "access-token-123", ExpiresIn: 300}))
+	}))
+	defer ts.Close()
+
+	a1 := NewOIDCAuthenticator(ts.URL, "client-id", "client-secret")
+	req, err := http.NewRequest(http.MethodGet, "http://example.test", nil)
+	assert.NoError(err)
+	assert.NoError(a1.Authenticate(req))
+	assert.Equal(1, requests)
+
+	// Invoke: a fresh authenticator instance for the same issuer/client
+	// should reuse the still-valid cached token rather than re-fetching.
+	a2 := NewOIDCAuthenticator(ts.URL, "client-id", "client-secret")
+	req2, err := http.NewRequest(http.MethodGet, "http://example.test", nil)
+	assert.NoError(err)
+	assert.NoError(a2.Authenticate(req2))
+
+	// Test
+	assert.Equal("Bearer access-token-123", req2.Header.Get("Authorization"))
+	assert.Equal(1, requests)
+}
+
+func TestOIDCAuthenticatorRefetchesWhenCachedTokenExpired(t *testing.T) {
+	assert := assert.New(t)
+
+	// Setup
+	keyring.MockInit()
+	requests := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+		token := fmt.Sprintf("token-%d", requests)
+		assert.NoError(json.NewEncoder(w).Encode(struct {
+			AccessToken string `json:"access_token"`
+			ExpiresIn   int    `json:"expires_in"`
+		}{AccessToken: token, ExpiresIn: -1}))
+	}))
+	defer ts.Close()
+
+	a1 := NewOIDCAuthenticator(ts.URL, "client-id", "client-secret")
+	req, err := http.NewRequest(http.MethodGet, "http://example.test", nil)
+	assert.NoError(err)
+	assert.NoError(a1.Authenticate(req))
+	assert.Equal("Bearer token-1", req.Header.Get("Authorization"))
+
+	// Invoke: the cached token already expired (ExpiresIn was negative), so
+	// a fresh authenticator instance must re-contact the issuer.
+	a2 := NewOIDCAuthenticator(ts.URL, "client-id", "client-secret")
+	req2, err := http.NewRequest(http.MethodGet, "http://example.test", nil)
+	assert.NoError(err)
+	assert.NoError(a2.Authenticate(req2))
+
+	// Test
+	assert.Equal("Bearer token-2", req2.Header.Get("Authorization"))
+	assert.Equal(2, requests)
+}
@@ -0,0 +1,130 @@
+package api
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+)
+
+var retryPolicy = struct {
+	sync.Mutex
+	maxAttempts int
+	base        time.Duration
+	cap         time.Duration
+}{maxAttempts: 4, base: 500 * time.Millisecond, cap: 30 * time.Second}
+
+// RetryEvent describes a single retried request, emitted via RetryObserver.
+type RetryEvent struct {
+	Attempt      int
+	Wait         time.Duration
+	ApertureTxID string
+}
+
+// RetryObserver is called once per retry attempt so callers (e.g. the CLI)
+// can surface retry progress to the user. It's a no-op by default.
+var RetryObserver = func(RetryEvent) {}
+
+// SetRetryPolicy configures the total number of attempts (including the
+// initial request) request() makes for rate-limited (429) and transient
+// (502/503/504) responses, and the base/cap of the exponential backoff
+// applied between attempts.
+func SetRetryPolicy(maxAttempts int, base, cap time.Duration) {
+	retryPolicy.Lock()
+	defer retryPolicy.Unlock()
+	retryPolicy.maxAttempts = maxAttempts
+	retryPolicy.base = base
+	retryPolicy.cap = cap
+}
+
+func retryableClient() *retryablehttp.Client {
+	retryPolicy.Lock()
+	maxAttempts, base, cap := retryPolicy.maxAttempts, retryPolicy.base, retryPolicy.cap
+	retryPolicy.Unlock()
+
+	retries := maxAttempts - 1
+	if retries < 0 {
+		retries = 0
+	}
+
+	c := retryablehttp.NewClient()
+	c.Logger = nil
+	c.RetryMax = retries
+	c.RetryWaitMin = base
+	c.RetryWaitMax = cap
+	c.CheckRetry = checkRetry
+	c.Backoff = backoffWithJitter
+	c.HTTPClient.Timeout = Timeout
+	return c
+}
+
+// checkRetry retries rate limiting and transient upstream errors, but gives
+// up immediately once the caller's context is done so a cancelled request
+// doesn't keep retrying.
+func checkRetry(ctx context.Context, resp *http.Response, err error) (bool, error) {
+	if ctx.Err() != nil {
+		return false, ctx.Err()
+	}
+
+	if err != nil {
+		return true, nil
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true, nil
+	}
+	return false, nil
+}
+
+// backoffWithJitter honors a 429 response's Retry-After header (both the
+// delta-seconds and HTTP-date forms) and otherwise falls back to capped
+// exponential backoff with jitter. Each call reports a RetryEvent.
+func backoffWithJitter(min, max time.Duration, attemptNum int, resp *http.Response) time.Duration {
+	wait := retryAfter(resp)
+	if wait <= 0 {
+		wait = min * time.Duration(1<<uint(attemptNum))
+		if wait > max {
+			wait = max
+		}
+		wait += time.Duration(rand.Int63n(int64(wait/2 + 1)))
+		if wait > max {
+			wait = max
+		}
+	}
+
+	var txID string
+	if resp != nil {
+		if ids := resp.Header["Aperture-Tx-Id"]; len(ids) > 0 {
+			txID = ids[0]
+		}
+	}
+	RetryObserver(RetryEvent{Attempt: attemptNum + 1, Wait: wait, ApertureTxID: txID})
+
+	return wait
+}
+
+func retryAfter(resp *http.Response) time.Duration {
+	if resp == nil || resp.StatusCode != http.StatusTooManyRequests {
+		return 0
+	}
+
+	ra := resp.Header.Get("Retry-After")
+	if ra == "" {
+		return 0
+	}
+
+	if secs, err := strconv.Atoi(ra); err == nil {
+		return time.Duration(secs) * time.Second
+	}
+
+	if t, err := http.ParseTime(ra); err == nil {
+		return time.Until(t)
+	}
+
+	return 0
+}
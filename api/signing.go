@@ -0,0 +1,86 @@
+package api
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SigningKey, when set, causes request() to attach an HMAC-SHA256 signature
+// to every outgoing request, for tamper-evident admin operations (account
+// and user mutations). Leave nil/empty to disable signing.
+var SigningKey []byte
+
+// maxSignatureSkew is how far a signed request's X-Section-Timestamp may
+// drift from the verifier's clock before VerifySignature rejects it.
+const maxSignatureSkew = 5 * time.Minute
+
+// signRequest attaches X-Section-Signature and X-Section-Timestamp headers
+// computed over req's method, path and body, so the server (or a test
+// double) can detect tampering via VerifySignature.
+func signRequest(req *http.Request, body []byte, key []byte) {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("X-Section-Timestamp", timestamp)
+	req.Header.Set("X-Section-Signature", signature(req.Method, req.URL.Path, body, timestamp, key))
+}
+
+func signature(method, path string, body []byte, timestamp string, key []byte) string {
+	if path == "" {
+		path = "/"
+	}
+
+	bodyHash := sha256.Sum256(body)
+	payload := strings.Join([]string{method, path, hex.EncodeToString(bodyHash[:]), timestamp}, "\n")
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifySignature checks that r carries a valid X-Section-Signature for key
+// and that its X-Section-Timestamp isn't skewed from the current time by
+// more than five minutes. It mirrors signRequest so the same scheme can be
+// verified on both sides: the CLI signs, a server (or test double) verifies.
+func VerifySignature(r *http.Request, key []byte) error {
+	sig := r.Header.Get("X-Section-Signature")
+	if sig == "" {
+		return fmt.Errorf("request is missing X-Section-Signature")
+	}
+
+	timestamp := r.Header.Get("X-Section-Timestamp")
+	if timestamp == "" {
+		return fmt.Errorf("request is missing X-Section-Timestamp")
+	}
+
+	sec, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid X-Section-Timestamp: %w", err)
+	}
+
+	if skew := time.Since(time.Unix(sec, 0)); skew > maxSignatureSkew || skew < -maxSignatureSkew {
+		return fmt.Errorf("request timestamp is skewed by %s, rejecting", skew)
+	}
+
+	var body []byte
+	if r.Body != nil {
+		body, err = ioutil.ReadAll(r.Body)
+		if err != nil {
+			return err
+		}
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+
+	expected := signature(r.Method, r.URL.Path, body, timestamp, key)
+	if !hmac.Equal([]byte(expected), []byte(sig)) {
+		return fmt.Errorf("invalid request signature")
+	}
+
+	return nil
+}
@@ -0,0 +1,105 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignRequestProducesValidSignature(t *testing.T) {
+	assert := assert.New(t)
+
+	// Setup
+	key := []byte("s3cr3t-signing-key")
+	req, err := http.NewRequest(http.MethodPost, "https://example.com/account/1", bytes.NewReader([]byte(`{"is_admin":true}`)))
+	assert.NoError(err)
+
+	// Invoke
+	signRequest(req, []byte(`{"is_admin":true}`), key)
+
+	// Test
+	assert.NotEmpty(req.Header.Get("X-Section-Signature"))
+	assert.NotEmpty(req.Header.Get("X-Section-Timestamp"))
+	assert.NoError(VerifySignature(req, key))
+}
+
+func TestVerifySignatureRejectsMutatedBody(t *testing.T) {
+	assert := assert.New(t)
+
+	// Setup
+	key := []byte("s3cr3t-signing-key")
+	signed, err := http.NewRequest(http.MethodPost, "https://example.com/account/1", bytes.NewReader([]byte(`{"is_admin":true}`)))
+	assert.NoError(err)
+	signRequest(signed, []byte(`{"is_admin":true}`), key)
+
+	// Invoke: same signature/timestamp, mutated body
+	tampered, err := http.NewRequest(http.MethodPost, "https://example.com/account/1", bytes.NewReader([]byte(`{"is_admin":false}`)))
+	assert.NoError(err)
+	tampered.Header.Set("X-Section-Signature", signed.Header.Get("X-Section-Signature"))
+	tampered.Header.Set("X-Section-Timestamp", signed.Header.Get("X-Section-Timestamp"))
+
+	// Test
+	assert.Error(VerifySignature(tampered, key))
+}
+
+func TestVerifySignatureRejectsClockSkew(t *testing.T) {
+	assert := assert.New(t)
+
+	// Setup
+	key := []byte("s3cr3t-signing-key")
+	req, err := http.NewRequest(http.MethodGet, "https://example.com/account", nil)
+	assert.NoError(err)
+
+	stale := time.Now().Add(-10 * time.Minute)
+	req.Header.Set("X-Section-Timestamp", formatUnix(stale))
+	req.Header.Set("X-Section-Signature", signature(req.Method, req.URL.Path, nil, formatUnix(stale), key))
+
+	// Invoke
+	err = VerifySignature(req, key)
+
+	// Test
+	assert.Error(err)
+	assert.Regexp("skewed", err)
+}
+
+func TestRequestSignsBodyWhenSigningKeySet(t *testing.T) {
+	assert := assert.New(t)
+
+	// Setup
+	key := []byte("s3cr3t-signing-key")
+	SigningKey = key
+
+	var verifyErr error
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		verifyErr = VerifySignature(r, key)
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	u, err := url.Parse(ts.URL)
+	assert.NoError(err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), Timeout)
+	defer cancel()
+
+	// Invoke
+	resp, err := request(ctx, http.MethodPost, *u, bytes.NewReader([]byte(`{"is_admin":true}`)))
+
+	// Test
+	assert.NoError(err)
+	assert.Equal(http.StatusOK, resp.StatusCode)
+	assert.NoError(verifyErr)
+
+	// Teardown
+	SigningKey = nil
+}
+
+func formatUnix(t time.Time) string {
+	return strconv.FormatInt(t.Unix(), 10)
+}
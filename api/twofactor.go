@@ -0,0 +1,147 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"sync"
+
+	"github.com/zalando/go-keyring"
+)
+
+const keyringKey2FASessionToken = "2fa-session-token"
+
+// sessionTokenHeader carries the session token issued after a successful
+// 2FA challenge on every subsequent request, alongside the primary
+// credential, so the server knows the second factor was satisfied.
+const sessionTokenHeader = "X-Section-2FA-Token"
+
+var (
+	sessionTokenMu     sync.Mutex
+	sessionToken       string
+	sessionTokenLoaded bool
+)
+
+// current2FASessionToken returns the session token from a prior successful
+// 2FA challenge, loading it from the keyring on first use within the
+// process so a session survives across CurrentUser calls (and processes).
+func current2FASessionToken() string {
+	sessionTokenMu.Lock()
+	defer sessionTokenMu.Unlock()
+
+	if !sessionTokenLoaded {
+		if token, err := keyring.Get(keyringService, keyringKey2FASessionToken); err == nil {
+			sessionToken = token
+		}
+		sessionTokenLoaded = true
+	}
+	return sessionToken
+}
+
+func set2FASessionToken(token string) {
+	sessionTokenMu.Lock()
+	sessionToken = token
+	sessionTokenLoaded = true
+	sessionTokenMu.Unlock()
+}
+
+// Err2FARequired is returned when the API challenges a request for a second
+// authentication factor. ChallengeID identifies the pending challenge and
+// must be passed back to SubmitOTP or SubmitRecoveryCode.
+type Err2FARequired struct {
+	ChallengeID string
+}
+
+func (e *Err2FARequired) Error() string {
+	return fmt.Sprintf("two-factor authentication required (challenge %s)", e.ChallengeID)
+}
+
+// OTPPrompt collects a one-time passcode (or recovery code) from the user for
+// a pending 2FA challenge. It's a variable so callers (and tests) can replace
+// stdin prompting with something else.
+var OTPPrompt = defaultOTPPrompt
+
+func defaultOTPPrompt(challengeID string) (code string, err error) {
+	fmt.Print("Enter your 2FA code (or a recovery code): ")
+	_, err = fmt.Scanln(&code)
+	return code, err
+}
+
+// otpCodePattern matches a TOTP code: 6 decimal digits. Anything else
+// entered at the prompt is treated as a recovery code.
+var otpCodePattern = regexp.MustCompile(`^[0-9]{6}$`)
+
+// isOTPCode reports whether code looks like a TOTP code rather than a
+// recovery code, so CurrentUser can route it to the matching endpoint.
+func isOTPCode(code string) bool {
+	return otpCodePattern.MatchString(code)
+}
+
+// SubmitOTP submits a TOTP code for a pending 2FA challenge. On success the
+// resulting session token is persisted in the keyring alongside the primary
+// token and returned to the caller.
+func SubmitOTP(challengeID, code string) (token string, err error) {
+	return submit2FA("/user/2fa/verify", challengeID, code)
+}
+
+// SubmitRecoveryCode submits a recovery code for a pending 2FA challenge, for
+// use when the user doesn't have access to their TOTP device.
+func SubmitRecoveryCode(challengeID, code string) (token string, err error) {
+	return submit2FA("/user/2fa/recovery", challengeID, code)
+}
+
+func submit2FA(path, challengeID, code string) (token string, err error) {
+	u := BaseURL()
+	u.Path += path
+
+	payload, err := json.Marshal(struct {
+		ChallengeID string `json:"challenge_id"`
+		Code        string `json:"code"`
+	}{challengeID, code})
+	if err != nil {
+		return "", err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), Timeout)
+	defer cancel()
+
+	resp, err := request(ctx, http.MethodPost, u, bytes.NewReader(payload))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		switch resp.StatusCode {
+		case http.StatusUnauthorized:
+			return "", ErrStatusUnauthorized
+		case http.StatusForbidden:
+			return "", ErrStatusForbidden
+		default:
+			return "", prettyTxIDError(resp)
+		}
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	var result struct {
+		Token string `json:"token"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return "", err
+	}
+
+	if err := keyring.Set(keyringService, keyringKey2FASessionToken, result.Token); err != nil {
+		return "", err
+	}
+	set2FASessionToken(result.Token)
+
+	return result.Token, nil
+}
@@ -0,0 +1,126 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zalando/go-keyring"
+)
+
+func TestCurrentUserCompletesTwoFactorChallenge(t *testing.T) {
+	assert := assert.New(t)
+
+	// Setup
+	keyring.MockInit()
+	sessionTokenLoaded = false
+	sessionToken = ""
+
+	attempt := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/user":
+			attempt++
+			if attempt == 1 {
+				w.Header().Set("X-Section-2FA-Required", "challenge-123")
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			assert.Equal("2fa-session-token", r.Header.Get(sessionTokenHeader))
+			w.WriteHeader(http.StatusOK)
+			assert.NoError(json.NewEncoder(w).Encode(User{ID: 42}))
+		case "/user/2fa/verify":
+			w.WriteHeader(http.StatusOK)
+			assert.NoError(json.NewEncoder(w).Encode(map[string]string{"token": "2fa-session-token"}))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	assert.NoError(os.Setenv("SECTION_API_URL", ts.URL))
+	OTPPrompt = func(challengeID string) (string, error) {
+		assert.Equal("challenge-123", challengeID)
+		return "123456", nil
+	}
+
+	// Invoke
+	u, err := CurrentUser()
+
+	// Test
+	assert.NoError(err)
+	assert.Equal(42, u.ID)
+	assert.Equal(2, attempt)
+	assert.Equal("2fa-session-token", current2FASessionToken())
+
+	// Teardown
+	assert.NoError(os.Unsetenv("SECTION_API_URL"))
+	OTPPrompt = defaultOTPPrompt
+	sessionTokenLoaded = false
+	sessionToken = ""
+}
+
+func TestCurrentUserRoutesRecoveryCodeToRecoveryEndpoint(t *testing.T) {
+	assert := assert.New(t)
+
+	// Setup
+	keyring.MockInit()
+	sessionTokenLoaded = false
+	sessionToken = ""
+
+	attempt := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/user":
+			attempt++
+			if attempt == 1 {
+				w.Header().Set("X-Section-2FA-Required", "challenge-123")
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			assert.Equal("2fa-session-token", r.Header.Get(sessionTokenHeader))
+			w.WriteHeader(http.StatusOK)
+			assert.NoError(json.NewEncoder(w).Encode(User{ID: 42}))
+		case "/user/2fa/recovery":
+			w.WriteHeader(http.StatusOK)
+			assert.NoError(json.NewEncoder(w).Encode(map[string]string{"token": "2fa-session-token"}))
+		case "/user/2fa/verify":
+			assert.FailNow("recovery code should not be submitted to the TOTP endpoint")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	assert.NoError(os.Setenv("SECTION_API_URL", ts.URL))
+	OTPPrompt = func(challengeID string) (string, error) {
+		assert.Equal("challenge-123", challengeID)
+		return "a1b2-recovery-code", nil
+	}
+
+	// Invoke
+	u, err := CurrentUser()
+
+	// Test
+	assert.NoError(err)
+	assert.Equal(42, u.ID)
+	assert.Equal(2, attempt)
+
+	// Teardown
+	assert.NoError(os.Unsetenv("SECTION_API_URL"))
+	OTPPrompt = defaultOTPPrompt
+	sessionTokenLoaded = false
+	sessionToken = ""
+}
+
+func TestIsOTPCode(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.True(isOTPCode("123456"))
+	assert.False(isOTPCode("12345"))
+	assert.False(isOTPCode("a1b2-recovery-code"))
+	assert.False(isOTPCode(""))
+}
@@ -3,25 +3,184 @@ package api
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io/ioutil"
 	"net/http"
+	"net/mail"
+	"net/url"
 )
 
+// Email is a validated email address.
+type Email string
+
+// UnmarshalJSON validates the address while unmarshaling.
+func (e *Email) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+
+	if s != "" {
+		if _, err := mail.ParseAddress(s); err != nil {
+			return fmt.Errorf("invalid email address %q: %w", s, err)
+		}
+	}
+
+	*e = Email(s)
+	return nil
+}
+
+func (e Email) String() string {
+	return string(e)
+}
+
 // User represents a user account known to Section
 type User struct {
-	ID          int    `json:"id"`
-	Email       string `json:"email"`
-	FirstName   string `json:"first_name"`
-	LastName    string `json:"last_name"`
-	CompanyName string `json:"company_name"`
-	PhoneNumber string `json:"phone_number"`
-	Verified    bool   `json:"verified"`
-	Requires2FA bool   `json:"requires2fa"`
-	Enforce2FA  bool   `json:"enforce2fa"`
-}
-
-// CurrentUser returns details for the currently authenticated user
+	ID          int      `json:"id"`
+	Email       Email    `json:"email"`
+	FirstName   string   `json:"first_name"`
+	LastName    string   `json:"last_name"`
+	CompanyName string   `json:"company_name"`
+	PhoneNumber string   `json:"phone_number"`
+	Verified    bool     `json:"verified"`
+	Requires2FA bool     `json:"requires2fa"`
+	Enforce2FA  bool     `json:"enforce2fa"`
+	Photo       *url.URL `json:"-"`
+	URL         *url.URL `json:"-"`
+}
+
+// userAlias has the same fields as User but none of its methods, so it can
+// be embedded in rawUser without recursing back into User.UnmarshalJSON.
+type userAlias User
+
+// rawUser mirrors the wire format of both the legacy (flat, no photo/url)
+// and current h-card style (name/url/photo/email as first-class fields)
+// profile shapes. Photo and URL arrive as plain strings on the wire and are
+// parsed into *url.URL once the rest of the fields have decoded.
+type rawUser struct {
+	userAlias
+	Photo string `json:"photo"`
+	URL   string `json:"url"`
+}
+
+// UnmarshalJSON tolerates both the legacy flat schema, which omits photo and
+// url entirely, and the h-card style schema that includes them.
+func (u *User) UnmarshalJSON(data []byte) error {
+	var raw rawUser
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	*u = User(raw.userAlias)
+
+	if raw.Photo != "" {
+		photo, err := url.Parse(raw.Photo)
+		if err != nil {
+			return fmt.Errorf("invalid photo url: %w", err)
+		}
+		u.Photo = photo
+	}
+
+	if raw.URL != "" {
+		profileURL, err := url.Parse(raw.URL)
+		if err != nil {
+			return fmt.Errorf("invalid profile url: %w", err)
+		}
+		u.URL = profileURL
+	}
+
+	return nil
+}
+
+// Profile fetches the caller's h-card style profile (name, url, photo,
+// email) and merges any richer fields it carries into u.
+func (u *User) Profile() error {
+	ur := BaseURL()
+	ur.Path += "/user/profile"
+
+	ctx, cancel := context.WithTimeout(context.Background(), Timeout)
+	defer cancel()
+
+	resp, err := request(ctx, http.MethodGet, ur, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		switch resp.StatusCode {
+		case http.StatusUnauthorized:
+			return ErrStatusUnauthorized
+		case http.StatusForbidden:
+			return ErrStatusForbidden
+		default:
+			return prettyTxIDError(resp)
+		}
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	var profile User
+	if err := json.Unmarshal(body, &profile); err != nil {
+		return err
+	}
+
+	u.merge(profile)
+	return nil
+}
+
+func (u *User) merge(other User) {
+	if other.FirstName != "" {
+		u.FirstName = other.FirstName
+	}
+	if other.LastName != "" {
+		u.LastName = other.LastName
+	}
+	if other.Email != "" {
+		u.Email = other.Email
+	}
+	if other.Photo != nil {
+		u.Photo = other.Photo
+	}
+	if other.URL != nil {
+		u.URL = other.URL
+	}
+}
+
+// CurrentUser returns details for the currently authenticated user. If the
+// API challenges the request for a second factor, the user is prompted for
+// an OTP (or recovery code) via OTPPrompt and the request is retried once
+// the challenge is satisfied.
 func CurrentUser() (u User, err error) {
+	u, err = currentUser()
+
+	var challenge *Err2FARequired
+	if errors.As(err, &challenge) {
+		code, err := OTPPrompt(challenge.ChallengeID)
+		if err != nil {
+			return u, err
+		}
+
+		if isOTPCode(code) {
+			_, err = SubmitOTP(challenge.ChallengeID, code)
+		} else {
+			_, err = SubmitRecoveryCode(challenge.ChallengeID, code)
+		}
+		if err != nil {
+			return u, err
+		}
+
+		return currentUser()
+	}
+
+	return u, err
+}
+
+func currentUser() (u User, err error) {
 	ur := BaseURL()
 	ur.Path += "/user"
 
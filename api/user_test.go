@@ -0,0 +1,57 @@
+package api
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserUnmarshalJSONHandlesLegacySchema(t *testing.T) {
+	assert := assert.New(t)
+
+	// Setup
+	bytes := helperLoadBytes(t, "user_legacy.json")
+
+	// Invoke
+	var u User
+	err := json.Unmarshal(bytes, &u)
+
+	// Test
+	assert.NoError(err)
+	assert.Equal(Email("ada@example.com"), u.Email)
+	assert.Nil(u.Photo)
+	assert.Nil(u.URL)
+}
+
+func TestUserUnmarshalJSONHandlesHCardSchema(t *testing.T) {
+	assert := assert.New(t)
+
+	// Setup
+	bytes := helperLoadBytes(t, "user_profile.json")
+
+	// Invoke
+	var u User
+	err := json.Unmarshal(bytes, &u)
+
+	// Test
+	assert.NoError(err)
+	assert.Equal(Email("ada@example.com"), u.Email)
+	if assert.NotNil(u.Photo) {
+		assert.Equal("https://example.com/ada.jpg", u.Photo.String())
+	}
+	if assert.NotNil(u.URL) {
+		assert.Equal("https://ada.example.com", u.URL.String())
+	}
+}
+
+func TestUserUnmarshalJSONRejectsInvalidEmail(t *testing.T) {
+	assert := assert.New(t)
+
+	// Invoke
+	var u User
+	err := json.Unmarshal([]byte(`{"id": 1, "email": "not-an-email"}`), &u)
+
+	// Test
+	assert.Error(err)
+}